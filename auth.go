@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+// oauth2Config is a profile's [profile.oauth2] table: enough to drive a
+// client-credentials or refresh-token grant against TokenURL.
+type oauth2Config struct {
+	ClientID     string `toml:"client_id"`
+	ClientSecret string `toml:"client_secret"`
+	TokenURL     string `toml:"token_url"`
+	GrantType    string `toml:"grant_type"` // "client_credentials" or "refresh_token"
+	RefreshToken string `toml:"refresh_token"`
+	Scope        string `toml:"scope"`
+}
+
+// oauth2Token is the access/refresh token pair resolveOAuth2Token works
+// with. RefreshToken is deliberately excluded from JSON: sessionData is
+// written to a plaintext file, and the refresh token already has its own,
+// more guarded home via storeRefreshToken/loadRefreshToken (OS keyring,
+// falling back to a 0600 file).
+type oauth2Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"-"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+func (t *oauth2Token) expired() bool {
+	return t == nil || t.AccessToken == "" || time.Until(t.Expiry) < 30*time.Second
+}
+
+// fetchToken performs cfg's grant against TokenURL. refreshToken overrides
+// cfg.RefreshToken when the grant is "refresh_token" and a session has a
+// newer one on file.
+func (cfg *oauth2Config) fetchToken(refreshToken string) (*oauth2Token, error) {
+	grantType := cfg.GrantType
+	if grantType == "" {
+		grantType = "client_credentials"
+	}
+
+	values := url.Values{}
+	values.Set("grant_type", grantType)
+	values.Set("client_id", cfg.ClientID)
+	values.Set("client_secret", cfg.ClientSecret)
+	if cfg.Scope != "" {
+		values.Set("scope", cfg.Scope)
+	}
+	if grantType == "refresh_token" {
+		rt := refreshToken
+		if rt == "" {
+			rt = cfg.RefreshToken
+		}
+		if rt == "" {
+			return nil, fmt.Errorf("oauth2: refresh_token grant requires a refresh token")
+		}
+		values.Set("refresh_token", rt)
+	}
+
+	resp, err := http.PostForm(cfg.TokenURL, values)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("oauth2: token endpoint returned %s", resp.Status)
+	}
+
+	var body struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("oauth2: decoding token response: %w", err)
+	}
+
+	token := &oauth2Token{
+		AccessToken:  body.AccessToken,
+		RefreshToken: body.RefreshToken,
+		Expiry:       time.Now().Add(time.Duration(body.ExpiresIn) * time.Second),
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+// applyAuth sets req's Authorization header according to authType. For
+// "oauth2" it resolves (and refreshes, and persists) a token through the
+// named session; for the others it just formats rawAuth.
+func applyAuth(req *request, session, authType string, cfg *oauth2Config, rawAuth string) error {
+	switch authType {
+	case "", "raw":
+		if rawAuth != "" {
+			req.head.Set("Authorization", rawAuth)
+		}
+	case "basic":
+		if rawAuth != "" {
+			req.head.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(rawAuth)))
+		}
+	case "bearer":
+		if rawAuth != "" {
+			req.head.Set("Authorization", "Bearer "+rawAuth)
+		}
+	case "oauth2":
+		if cfg == nil {
+			return fmt.Errorf("--auth-type oauth2 requires an [profile.oauth2] config")
+		}
+		token, err := resolveOAuth2Token(session, cfg)
+		if err != nil {
+			return err
+		}
+		req.head.Set("Authorization", "Bearer "+token)
+	default:
+		return fmt.Errorf("unknown --auth-type %q", authType)
+	}
+	return nil
+}
+
+// resolveOAuth2Token returns a valid access token for cfg, refreshing (and
+// persisting the refreshed token under session, if given) when the
+// previously stored one is missing or close to expiry.
+func resolveOAuth2Token(session string, cfg *oauth2Config) (string, error) {
+	var current *oauth2Token
+	if session != "" {
+		data, err := loadSession(session)
+		if err != nil {
+			return "", err
+		}
+		current = data.OAuth2
+	}
+	if !current.expired() {
+		return current.AccessToken, nil
+	}
+
+	refreshToken := cfg.RefreshToken
+	if session != "" {
+		if rt, err := loadRefreshToken(session); err == nil && rt != "" {
+			refreshToken = rt
+		}
+	}
+
+	token, err := cfg.fetchToken(refreshToken)
+	if err != nil {
+		return "", err
+	}
+
+	if session != "" {
+		data, err := loadSession(session)
+		if err != nil {
+			return "", err
+		}
+		data.OAuth2 = token
+		if err := saveSessionData(session, data); err != nil {
+			return "", err
+		}
+		if token.RefreshToken != "" {
+			if err := storeRefreshToken(session, token.RefreshToken); err != nil {
+				return "", err
+			}
+		}
+	}
+	return token.AccessToken, nil
+}
+
+func keyringService(session string) string {
+	return "req-session-" + session
+}
+
+// storeRefreshToken saves token in the OS keyring, falling back to a
+// 0600 file next to the session when no keyring backend is available.
+func storeRefreshToken(session, token string) error {
+	if err := keyring.Set(keyringService(session), "refresh_token", token); err == nil {
+		return nil
+	}
+	path, err := sessionPath(session)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path+".refresh_token", []byte(token), 0o600)
+}
+
+func loadRefreshToken(session string) (string, error) {
+	if token, err := keyring.Get(keyringService(session), "refresh_token"); err == nil {
+		return token, nil
+	}
+	path, err := sessionPath(session)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path + ".refresh_token")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Global flags shared by every subcommand that builds and sends a request.
+// They're bound once on rootCmd's persistent flag set rather than threaded
+// through as parameters, mirroring how apigen wires its cobra commands.
+var (
+	flagHost         string
+	flagPath         string
+	flagScheme       string
+	flagFormat       string
+	flagHeaders      []string
+	flagAuth         string
+	flagSelect       string
+	flagProfile      string
+	flagDebug        bool
+	flagVerbose      bool
+	flagTimeout      time.Duration
+	flagRetry        int
+	flagRetryBackoff time.Duration
+	flagMaxRedirects int
+	flagNoRedirect   bool
+	flagInsecure     bool
+	flagCACert       string
+	flagCert         string
+	flagKey          string
+	flagSpec         string
+	flagSession      string
+	flagAuthType     string
+	flagUploadResume bool
+	flagOutput       string
+	flagContinue     bool
+	flagRecord       string
+)
+
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "req",
+		Short: "A command line HTTP client",
+		Args:  cobra.ArbitraryArgs,
+		// Bare invocations (`req GET users`) behave like `req do GET users`.
+		RunE: runDo,
+	}
+	bindGlobalFlags(root)
+
+	root.ValidArgsFunction = specCompletions
+	root.AddCommand(newDoCmd())
+	root.AddCommand(newSessionCmd())
+	root.AddCommand(newImportCmd())
+	root.AddCommand(newReplayCmd())
+	return root
+}
+
+// specCompletions drives `req completion bash|zsh|fish|powershell` (cobra's
+// built-in machinery, wired up automatically by AddCommand) with dynamic
+// suggestions sourced from --spec: the method for the first positional arg,
+// then that operation's path segments for the rest.
+func specCompletions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if flagSpec == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	spec, err := loadSpec(flagSpec)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	seen := make(map[string]bool)
+	var out []string
+	for _, line := range spec.operationSummaries() {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		method, segments := fields[0], splitPath(fields[1])
+
+		var candidate string
+		switch {
+		case len(args) == 0:
+			candidate = method
+		case !strings.EqualFold(args[0], method):
+			continue
+		case len(args)-1 < len(segments):
+			candidate = segments[len(args)-1]
+		default:
+			continue
+		}
+		if !seen[candidate] {
+			seen[candidate] = true
+			out = append(out, candidate)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+func bindGlobalFlags(cmd *cobra.Command) {
+	f := cmd.PersistentFlags()
+	f.StringVar(&flagHost, "host", "", "target host")
+	f.StringVar(&flagPath, "path", "", "base request path")
+	f.StringVar(&flagScheme, "scheme", "", "URL scheme (default http)")
+	f.StringVar(&flagFormat, "format", "", "body encoding: json or form")
+	f.StringArrayVarP(&flagHeaders, "header", "H", nil, "request header, key:value (repeatable)")
+	f.StringVar(&flagAuth, "auth", "", "Authorization header value")
+	f.StringVar(&flagSelect, "select", "", "JMESPath-like expression to filter a JSON response")
+	f.StringVar(&flagProfile, "profile", "", "named config profile to load")
+	f.BoolVarP(&flagDebug, "debug", "d", false, "dump the raw request/response")
+	f.BoolVarP(&flagVerbose, "verbose", "v", false, "alias for --debug")
+	f.DurationVar(&flagTimeout, "timeout", 0, "per-request timeout (0 disables)")
+	f.IntVar(&flagRetry, "retry", 0, "number of retries on retriable errors")
+	f.DurationVar(&flagRetryBackoff, "retry-backoff", 500*time.Millisecond, "base backoff between retries")
+	f.IntVar(&flagMaxRedirects, "max-redirects", 0, "max redirects to follow (0: unlimited)")
+	f.BoolVar(&flagNoRedirect, "no-redirect", false, "don't follow redirects")
+	f.BoolVar(&flagInsecure, "insecure", false, "skip TLS certificate verification")
+	f.StringVar(&flagCACert, "cacert", "", "CA certificate bundle to trust")
+	f.StringVar(&flagCert, "cert", "", "client TLS certificate")
+	f.StringVar(&flagKey, "key", "", "client TLS key")
+	f.StringVar(&flagSpec, "spec", "", "OpenAPI 3 document to resolve and validate requests against")
+	f.StringVar(&flagSession, "session", "", "named session to load cookies/auth from and persist Set-Cookie back to")
+	f.StringVar(&flagAuthType, "auth-type", "", "how to apply --auth: raw (default), basic, bearer, or oauth2")
+	f.BoolVar(&flagUploadResume, "upload-resume", false, "resume a single-file upload via a Content-Range/Upload-Offset probe")
+	f.StringVarP(&flagOutput, "output", "o", "", "write the response body to this file instead of stdout")
+	f.BoolVarP(&flagContinue, "continue", "c", false, "resume a partial --output download via a Range request")
+	f.StringVar(&flagRecord, "record", "", "capture the built request to a file `req replay` can resend")
+}
+
+// newRequestFromFlags resolves the profile (--profile or $REQ_PROFILE),
+// builds a request seeded from it, and layers environment variables and
+// global flags on top, in that order of precedence.
+func newRequestFromFlags() (*request, error) {
+	name := flagProfile
+	if name == "" {
+		name = os.Getenv("REQ_PROFILE")
+	}
+	p, err := loadProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	req := newRequest(p)
+	if h := os.Getenv("REQ_HOST"); h != "" {
+		req.host = h
+	}
+	if pth := os.Getenv("REQ_PATH"); pth != "" {
+		req.path = splitPath(pth)
+	}
+	if f := os.Getenv("REQ_FORMAT"); f != "" {
+		req.format = f
+	}
+
+	if flagHost != "" {
+		req.host = flagHost
+	}
+	if flagPath != "" {
+		req.path = splitPath(flagPath)
+	}
+	if flagScheme != "" {
+		req.scheme = flagScheme
+	}
+	if req.scheme == "" {
+		req.scheme = "http"
+	}
+	if flagFormat != "" {
+		switch flagFormat {
+		case "json", "form":
+			req.format = flagFormat
+		default:
+			return nil, fmt.Errorf("unknown format %q", flagFormat)
+		}
+	}
+	if flagSession != "" {
+		if _, err := applySession(req, flagSession); err != nil {
+			return nil, err
+		}
+		req.sessionName = flagSession
+	}
+
+	for _, h := range flagHeaders {
+		if err := req.addHeader(h); err != nil {
+			return nil, err
+		}
+	}
+
+	rawAuth := flagAuth
+	authType := flagAuthType
+	var oauth2Cfg *oauth2Config
+	if p != nil {
+		if rawAuth == "" {
+			if token, err := p.authToken(); err == nil {
+				rawAuth = token
+			}
+		}
+		if authType == "" {
+			authType = p.AuthType
+		}
+		oauth2Cfg = p.OAuth2
+	}
+	if err := applyAuth(req, flagSession, authType, oauth2Cfg, rawAuth); err != nil {
+		return nil, err
+	}
+
+	if flagSelect != "" {
+		req.selectExpr = flagSelect
+	}
+	req.debug = flagDebug || flagVerbose
+
+	req.client.timeout = flagTimeout
+	req.client.retry = flagRetry
+	req.client.retryBackoff = flagRetryBackoff
+	req.client.maxRedirects = flagMaxRedirects
+	req.client.noRedirect = flagNoRedirect
+	req.client.insecure = flagInsecure
+	req.client.caCert = flagCACert
+	req.client.cert = flagCert
+	req.client.key = flagKey
+	req.client.uploadResume = flagUploadResume
+
+	req.outputPath = flagOutput
+	req.continueDownload = flagContinue
+	req.recordPath = flagRecord
+
+	specPath := flagSpec
+	if specPath == "" && p != nil {
+		specPath = p.Spec
+	}
+	if specPath != "" {
+		req.spec, err = loadSpec(specPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+func newDoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "do <method> <path> [<path> ...] [-- <key>=<value> ...]",
+		Short:             "Build and send a request (the default behavior of req)",
+		Args:              cobra.ArbitraryArgs,
+		RunE:              runDo,
+		ValidArgsFunction: specCompletions,
+	}
+}
+
+// runDo implements both `req <method> ...` and `req do <method> ...`.
+// pflag leaves everything after a literal "--" in Args() and records its
+// offset via ArgsLenAtDash, so the method/path positionals and the
+// key=value body pairs are split on that rather than on a hand-rolled
+// state machine.
+func runDo(cmd *cobra.Command, _ []string) error {
+	args := cmd.Flags().Args()
+	dashAt := cmd.ArgsLenAtDash()
+
+	pathArgs, kvArgs := args, []string(nil)
+	if dashAt != -1 {
+		pathArgs, kvArgs = args[:dashAt], args[dashAt:]
+	}
+	if len(pathArgs) == 0 {
+		return errors.New("no method given")
+	}
+
+	req, err := newRequestFromFlags()
+	if err != nil {
+		return err
+	}
+	req.method = strings.ToUpper(pathArgs[0])
+	for _, arg := range pathArgs[1:] {
+		if req.host == "" {
+			req.host = arg
+		} else {
+			req.path = append(req.path, arg)
+		}
+	}
+
+	for _, arg := range kvArgs {
+		if err := applyKV(req, arg); err != nil {
+			return err
+		}
+	}
+
+	if req.spec != nil {
+		op, resolvedPath, err := req.spec.findOperation(req.method, req.path, req.body)
+		if err != nil {
+			return err
+		}
+		req.path = resolvedPath
+		for _, p := range op.Parameters {
+			if p.In == "path" {
+				delete(req.body, p.Name)
+			}
+		}
+		if err := op.validateBody(req.body); err != nil {
+			return fmt.Errorf("request does not match %s: %w", op.OperationID, err)
+		}
+		req.specOp = op
+	}
+
+	return execute(req)
+}
+
+// applyKV parses a single `key=value` body argument, honoring `@file`
+// uploads and JSON vs. form encoding, exactly as the old parseArgs did.
+func applyKV(req *request, arg string) error {
+	key, value, ok := splitKV(arg, "=")
+	if !ok {
+		return fmt.Errorf("key-value pair %q is invalid", arg)
+	}
+	if strings.HasPrefix(value, "@") {
+		req.file[key] = strings.TrimPrefix(value, "@")
+		return nil
+	}
+	if req.format != "" && req.format != "json" {
+		req.body[key] = value
+		return nil
+	}
+	value = wrapString(value)
+	var v interface{}
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return err
+	}
+	req.body[key] = v
+	return nil
+}
+
+// execute builds the HTTP request, sends it through the configured
+// client (with retries), and renders the response the way main() used to.
+func execute(req *request) error {
+	if req.outputPath != "" && req.continueDownload {
+		if info, err := os.Stat(req.outputPath); err == nil && info.Size() > 0 {
+			req.head.Set("Range", fmt.Sprintf("bytes=%d-", info.Size()))
+		}
+	}
+
+	r, err := req.build()
+	if err != nil {
+		return err
+	}
+	if req.recordPath != "" {
+		if len(req.file) != 0 {
+			return fmt.Errorf("--record: capturing multipart/resumable uploads isn't supported")
+		}
+		if err := recordRequest(req.recordPath, r); err != nil {
+			return fmt.Errorf("--record: %w", err)
+		}
+	}
+	if req.debug {
+		r.Write(os.Stdout)
+	}
+
+	httpClient, err := req.client.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := do(httpClient, r, req.client)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if req.sessionName != "" && req.client.jar != nil {
+		if err := persistSessionCookies(req.sessionName, req.client.jar, r.URL); err != nil {
+			fmt.Fprintln(os.Stderr, "warning: saving session cookies:", err)
+		}
+	}
+
+	if req.outputPath != "" {
+		return downloadToFile(req.outputPath, resp, req.continueDownload)
+	}
+	if req.debug {
+		return resp.Write(os.Stdout)
+	}
+	if req.specOp != nil {
+		if err := checkResponseAgainstSpec(req.specOp, resp); err != nil {
+			fmt.Fprintln(os.Stderr, "warning:", err)
+		}
+	}
+	return renderResponse(req, resp)
+}
+
+// checkResponseAgainstSpec peeks at resp's JSON body to validate it against
+// the operation's declared response schema, then restores resp.Body so
+// renderResponse can still consume it normally.
+func checkResponseAgainstSpec(op *oaOperation, resp *http.Response) error {
+	raw, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil // not a JSON object; nothing to validate
+	}
+	if msg := op.validateResponse(resp.StatusCode, body); msg != "" {
+		return errors.New(msg)
+	}
+	return nil
+}
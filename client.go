@@ -0,0 +1,162 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+var retriableStatus = map[int]bool{
+	http.StatusRequestTimeout:     true,
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// clientConfig holds the flags that shape the *http.Client used to send a
+// request: timeouts, retries, redirect handling, and TLS material.
+type clientConfig struct {
+	timeout      time.Duration
+	retry        int
+	retryBackoff time.Duration
+	maxRedirects int
+	noRedirect   bool
+	insecure     bool
+	caCert       string
+	cert         string
+	key          string
+	jar          http.CookieJar
+	uploadResume bool
+}
+
+func newClientConfig() *clientConfig {
+	return &clientConfig{
+		retryBackoff: 500 * time.Millisecond,
+	}
+}
+
+// client builds an *http.Client from cfg, wiring up TLS material and a
+// redirect policy. Retries are handled by do(), not by the client itself.
+func (cfg *clientConfig) client() (*http.Client, error) {
+	transport := &http.Transport{}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.insecure}
+	if cfg.caCert != "" {
+		pool, err := loadCACert(cfg.caCert)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.cert != "" || cfg.key != "" {
+		if cfg.cert == "" || cfg.key == "" {
+			return nil, errors.New("--cert and --key must be given together")
+		}
+		pair, err := tls.LoadX509KeyPair(cfg.cert, cfg.key)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	c := &http.Client{
+		Timeout:   cfg.timeout,
+		Transport: transport,
+		Jar:       cfg.jar,
+	}
+	if cfg.noRedirect {
+		c.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	} else if cfg.maxRedirects > 0 {
+		max := cfg.maxRedirects
+		c.CheckRedirect = func(_ *http.Request, via []*http.Request) error {
+			if len(via) >= max {
+				return fmt.Errorf("stopped after %d redirects", max)
+			}
+			return nil
+		}
+	}
+	return c, nil
+}
+
+func loadCACert(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// do sends r using c, retrying on network errors and the status codes in
+// retriableStatus with exponential backoff plus jitter, honoring
+// Retry-After when the server sends one.
+func do(c *http.Client, r *http.Request, cfg *clientConfig) (resp *http.Response, err error) {
+	attempts := cfg.retry + 1
+	if r.Body != nil && r.GetBody == nil {
+		// http.NewRequest only populates GetBody for bodies it recognizes as
+		// replayable (*bytes.Buffer, *bytes.Reader, *strings.Reader). A
+		// streamed multipart upload or a resumable upload's *os.File isn't,
+		// so retrying it would resend an already-drained or partial body
+		// instead of the real request; don't retry rather than risk that.
+		attempts = 1
+	}
+	backoff := cfg.retryBackoff
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 && r.GetBody != nil {
+			r.Body, err = r.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err = c.Do(r)
+		if err == nil && !retriableStatus[resp.StatusCode] {
+			return resp, nil
+		}
+		if attempt == attempts-1 {
+			return resp, err
+		}
+
+		wait := backoff
+		if err == nil {
+			if after, ok := retryAfter(resp); ok {
+				wait = after
+			}
+			resp.Body.Close()
+		}
+		time.Sleep(jitter(wait))
+		backoff *= 2
+	}
+	return resp, err
+}
+
+func jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
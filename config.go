@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// profile is a named set of defaults loaded from the config file: the
+// target server, default headers/auth, and output format. Fields left
+// empty don't override whatever newRequest() would otherwise set.
+type profile struct {
+	Scheme      string            `toml:"scheme"`
+	Host        string            `toml:"host"`
+	Path        string            `toml:"path"`
+	Headers     map[string]string `toml:"headers"`
+	Auth        string            `toml:"auth"`
+	AuthCommand string            `toml:"auth_command"`
+	AuthType    string            `toml:"auth_type"`
+	OAuth2      *oauth2Config     `toml:"oauth2"`
+	Format      string            `toml:"format"`
+	Spec        string            `toml:"spec"`
+}
+
+// config is the on-disk shape of ~/.config/req/config.toml: a table of
+// named profiles. A "default" profile, if present, is applied to every
+// other profile before its own fields are layered on top.
+type config struct {
+	Profiles map[string]profile `toml:"profiles"`
+}
+
+func configPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "req", "config.toml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "req", "config.toml")
+}
+
+func loadConfig(path string) (*config, error) {
+	cfg := &config{}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if _, err := toml.DecodeFile(path, cfg); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// resolveProfile layers the "default" profile (if any) with the named
+// profile and returns the merged snapshot that newRequest() consumes.
+func (cfg *config) resolveProfile(name string) (*profile, error) {
+	merged := cfg.Profiles["default"]
+	if name == "" || name == "default" {
+		return &merged, nil
+	}
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+	if p.Scheme != "" {
+		merged.Scheme = p.Scheme
+	}
+	if p.Host != "" {
+		merged.Host = p.Host
+	}
+	if p.Path != "" {
+		merged.Path = p.Path
+	}
+	if p.Auth != "" {
+		merged.Auth = p.Auth
+	}
+	if p.AuthCommand != "" {
+		merged.AuthCommand = p.AuthCommand
+	}
+	if p.AuthType != "" {
+		merged.AuthType = p.AuthType
+	}
+	if p.OAuth2 != nil {
+		merged.OAuth2 = p.OAuth2
+	}
+	if p.Format != "" {
+		merged.Format = p.Format
+	}
+	if p.Spec != "" {
+		merged.Spec = p.Spec
+	}
+	if len(p.Headers) > 0 {
+		// merged.Headers still aliases the "default" profile's map at this
+		// point (struct copies don't deep-copy maps); clone it before
+		// writing so merging a named profile can't leak into "default".
+		cloned := make(map[string]string, len(merged.Headers)+len(p.Headers))
+		for k, v := range merged.Headers {
+			cloned[k] = v
+		}
+		for k, v := range p.Headers {
+			cloned[k] = v
+		}
+		merged.Headers = cloned
+	}
+	return &merged, nil
+}
+
+// authToken resolves the profile's auth material: either the literal
+// Auth value (with ${ENV} interpolation) or the output of AuthCommand.
+func (p *profile) authToken() (string, error) {
+	if p.AuthCommand != "" {
+		out, err := exec.Command("sh", "-c", p.AuthCommand).Output()
+		if err != nil {
+			return "", fmt.Errorf("auth_command: %w", err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	return os.Expand(p.Auth, envLookup), nil
+}
+
+func envLookup(key string) string {
+	return os.Getenv(key)
+}
+
+// loadProfile reads the config file and resolves name, returning nil if
+// there's no config file and no profile was requested.
+func loadProfile(name string) (*profile, error) {
+	path := configPath()
+	if path == "" {
+		return nil, nil
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" && len(cfg.Profiles) == 0 {
+		return nil, nil
+	}
+	return cfg.resolveProfile(name)
+}
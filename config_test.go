@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func newTestConfig() *config {
+	return &config{Profiles: map[string]profile{
+		"default": {
+			Scheme:  "https",
+			Headers: map[string]string{"X-Default": "d"},
+		},
+		"a": {
+			Host:    "a.example.com",
+			Headers: map[string]string{"X-A": "a"},
+		},
+		"b": {
+			Host:    "b.example.com",
+			Headers: map[string]string{"X-B": "b"},
+		},
+	}}
+}
+
+func TestResolveProfileLayersDefault(t *testing.T) {
+	cfg := newTestConfig()
+	p, err := cfg.resolveProfile("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Scheme != "https" {
+		t.Fatalf("Scheme = %q, want inherited from default", p.Scheme)
+	}
+	if p.Host != "a.example.com" {
+		t.Fatalf("Host = %q, want a.example.com", p.Host)
+	}
+	if p.Headers["X-Default"] != "d" || p.Headers["X-A"] != "a" {
+		t.Fatalf("Headers = %#v, want both X-Default and X-A", p.Headers)
+	}
+}
+
+func TestResolveProfileUnknown(t *testing.T) {
+	cfg := newTestConfig()
+	if _, err := cfg.resolveProfile("nope"); err == nil {
+		t.Fatal("expected an error for an unknown profile")
+	}
+}
+
+func TestResolveProfileDefault(t *testing.T) {
+	cfg := newTestConfig()
+	p, err := cfg.resolveProfile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Scheme != "https" {
+		t.Fatalf("Scheme = %q, want https", p.Scheme)
+	}
+}
+
+// TestResolveProfileDoesNotLeakAcrossCalls guards against the Headers map
+// aliasing bug: resolving profile "a" must not mutate the shared "default"
+// profile's map, or a later call resolving "b" would see "a"'s headers too.
+func TestResolveProfileDoesNotLeakAcrossCalls(t *testing.T) {
+	cfg := newTestConfig()
+	if _, err := cfg.resolveProfile("a"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := cfg.resolveProfile("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, leaked := b.Headers["X-A"]; leaked {
+		t.Fatalf("profile b leaked a's header: %#v", b.Headers)
+	}
+	if b.Headers["X-B"] != "b" || b.Headers["X-Default"] != "d" {
+		t.Fatalf("profile b missing its own/default headers: %#v", b.Headers)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Build a request from another tool's invocation",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "curl <curl-command>",
+		Short: "Parse a curl command line and send the equivalent request",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req, err := requestFromCurl(strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+			return execute(req)
+		},
+	})
+	return cmd
+}
+
+// requestFromCurl parses a (single-line, shell-quoted) curl invocation
+// into a *request. It understands the flags people actually paste out of
+// browser dev tools: -X/--request, -H/--header, -d/--data(-raw/-binary),
+// -u/--user, and a bare URL argument; anything else is ignored.
+func requestFromCurl(line string) (*request, error) {
+	tokens, err := splitShellWords(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) > 0 && tokens[0] == "curl" {
+		tokens = tokens[1:]
+	}
+
+	req := newRequest(nil)
+	req.client = newClientConfig()
+	var rawURL string
+	var data []string
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		next := func() (string, error) {
+			if i+1 >= len(tokens) {
+				return "", fmt.Errorf("curl: %s needs a value", t)
+			}
+			i++
+			return tokens[i], nil
+		}
+		switch {
+		case t == "-X" || t == "--request":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			req.method = strings.ToUpper(v)
+		case t == "-H" || t == "--header":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if err := req.addHeader(v); err != nil {
+				return nil, err
+			}
+		case t == "-d" || t == "--data" || t == "--data-raw" || t == "--data-binary":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, v)
+		case t == "-u" || t == "--user":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			req.head.Set("Authorization", basicAuthHeader(v))
+		case strings.HasPrefix(t, "-"):
+			// unrecognized flag (e.g. -s, -L, --compressed): ignore
+		default:
+			rawURL = t
+		}
+	}
+
+	if rawURL == "" {
+		return nil, fmt.Errorf("curl: no URL found")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("curl: %w", err)
+	}
+	req.scheme = u.Scheme
+	req.host = u.Host
+	req.path = splitPath(u.Path)
+	// Keep the curl URL's query string on the URL regardless of method;
+	// only -d/--data goes into the body below.
+	req.query = u.Query()
+
+	if req.method == "" {
+		if len(data) > 0 {
+			req.method = "POST"
+		} else {
+			req.method = "GET"
+		}
+	}
+	if len(data) > 0 {
+		// Match curl itself: -d/--data(-raw|-binary) is an opaque body
+		// (multiple occurrences joined with "&"), not key=value pairs, so a
+		// raw JSON --data-raw body round-trips instead of failing to parse
+		// as a key=value pair.
+		req.rawBody = strings.Join(data, "&")
+		if req.head.Get("Content-Type") == "" {
+			req.head.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	return req, nil
+}
+
+func basicAuthHeader(userpass string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(userpass))
+}
+
+// splitShellWords tokenizes a command line the way a POSIX shell would for
+// the subset req cares about: whitespace-separated words with single and
+// double quoting (no nested expansion, no escapes inside single quotes).
+func splitShellWords(s string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	var quote rune
+	inWord := false
+
+	flush := func() {
+		if inWord {
+			words = append(words, cur.String())
+			cur.Reset()
+			inWord = false
+		}
+	}
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		default:
+			inWord = true
+			cur.WriteRune(r)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("curl: unterminated %q quote", string(quote))
+	}
+	flush()
+	return words, nil
+}
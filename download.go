@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+)
+
+// downloadToFile streams resp's body to path with the same progress UI
+// mimeReader uses for uploads. When resume is true and the server
+// answered with 206 Partial Content (because execute() sent a Range
+// header), the file is appended to instead of truncated.
+func downloadToFile(path string, resp *http.Response, resume bool) error {
+	flags := os.O_CREATE | os.O_WRONLY
+	var startAt int64
+	if resume && resp.StatusCode == http.StatusPartialContent {
+		flags |= os.O_APPEND
+		if info, err := os.Stat(path); err == nil {
+			startAt = info.Size()
+		}
+	} else {
+		flags |= os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var total int64
+	if resp.ContentLength > 0 {
+		total = startAt + resp.ContentLength
+	}
+
+	body := newProgressReader(resp.Body, path, total).startAt(startAt)
+	_, err = io.Copy(f, body)
+	return err
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -18,168 +17,77 @@ import (
 var userAgent = "req-v0.0.1"
 
 func main() {
-	req := newRequest()
-	req.scheme = "http"
-	req.host = os.Getenv("REQ_HOST")
-	req.path = splitPath(os.Getenv("REQ_PATH"))
-	req.format = os.Getenv("REQ_FORMAT")
-
-	if len(os.Args) <= 2 {
-		fmt.Println("Usage: req [--host] [--path] [--header] [--auth] [--verbose] [--scheme] <method> <path> [<path> ...] [--] [<key>=<value> ...]")
-		os.Exit(1)
-	}
-
-	err := parseArgs(os.Args[1:], req)
-	if err != nil {
-		fatal(err)
-	}
-
-	r, err := req.build()
-	if err != nil {
+	if err := newRootCmd().Execute(); err != nil {
 		fatal(err)
 	}
+}
 
-	if req.debug {
-		r.Write(os.Stdout)
-	}
+type request struct {
+	scheme  string
+	host    string
+	method  string
+	path    []string
+	file    map[string]string
+	body    map[string]interface{}
+	rawBody string // opaque body (e.g. curl -d/--data-raw); bypasses body/format entirely
+	query   url.Values
+	head    url.Values
+	debug   bool
+	format  string
+
+	selectExpr string
+	client     *clientConfig
+
+	spec   *openAPISpec
+	specOp *oaOperation
+
+	sessionName string
+
+	outputPath       string
+	continueDownload bool
+
+	recordPath string
+}
 
-	resp, err := http.DefaultClient.Do(r)
-	if err != nil {
-		fatal(err)
+// newRequest builds a request, applying p (which may be nil) as the
+// starting point for scheme/host/path/headers/auth/format before flags
+// and environment variables layer on top.
+func newRequest(p *profile) *request {
+	req := &request{
+		file:   make(map[string]string),
+		body:   make(map[string]interface{}),
+		head:   url.Values{"User-Agent": []string{userAgent}},
+		client: newClientConfig(),
 	}
-	defer resp.Body.Close()
-
-	if req.debug {
-		err = resp.Write(os.Stdout)
-	} else {
-		_, err = io.Copy(os.Stdout, resp.Body)
+	if p == nil {
+		return req
 	}
-	if err != nil {
-		fatal(err)
+	if p.Scheme != "" {
+		req.scheme = p.Scheme
 	}
-}
-
-func parseArgs(args []string, req *request) (err error) {
-	var state int
-	for n, arg := range args {
-		switch state {
-		case -1:
-			state = 0
-		case 0:
-			switch arg {
-			case "-v", "--verbose", "-d", "--debug":
-				req.debug = true
-			case "--scheme":
-				if len(args) < n+1 {
-					return errors.New("no --scheme value")
-				}
-				req.scheme = args[n+1]
-				state = -1
-			case "--host":
-				if len(args) < n+1 {
-					return errors.New("no --host value")
-				}
-				req.host = args[n+1]
-				state = -1
-			case "--format":
-				if len(args) < n+1 {
-					return errors.New("no --format value")
-				}
-				f := args[n+1]
-				switch f {
-				case "json", "form":
-					req.format = f
-				default:
-					return fmt.Errorf("unknown format %q", f)
-				}
-				state = -1
-			case "--path":
-				if len(args) < n+1 {
-					return errors.New("no --path value")
-				}
-				req.path = splitPath(args[n+1])
-				state = -1
-			case "--head", "--header":
-				if len(args) < n+1 {
-					return fmt.Errorf("no %s value", arg)
-				}
-				if err = req.addHeader(args[n+1]); err != nil {
-					return
-				}
-				state = -1
-			case "--auth":
-				if len(args) < n+1 {
-					return errors.New("no --auth value")
-				}
-				req.head.Set("Authorization", args[n+1])
-				state = -1
-			default:
-				if strings.HasPrefix(arg, "-") {
-					return fmt.Errorf("unknown flag %q", arg)
-				}
-				req.method = strings.ToUpper(arg)
-				state = 1
-			}
-		case 1:
-			if arg == "--" {
-				state = 2
-			} else {
-				if req.host == "" {
-					req.host = arg
-				} else {
-					req.path = append(req.path, arg)
-				}
-			}
-		case 2:
-			key, value, ok := splitKV(arg, "=")
-			if !ok {
-				return fmt.Errorf("key-value pair %q is invalid", arg)
-			}
-			if strings.HasPrefix(value, "@") {
-				req.file[key] = strings.TrimPrefix(value, "@")
-			} else if req.format != "" && req.format != "json" {
-				req.body[key] = value
-			} else {
-				value = wrapString(value)
-				var v interface{}
-				err = json.Unmarshal([]byte(value), &v)
-				if err != nil {
-					return
-				}
-				req.body[key] = v
-			}
-		}
+	req.host = p.Host
+	if p.Path != "" {
+		req.path = splitPath(p.Path)
 	}
-	return
-}
-
-type request struct {
-	scheme string
-	host   string
-	method string
-	path   []string
-	file   map[string]string
-	body   map[string]interface{}
-	head   url.Values
-	debug  bool
-	format string
-}
-
-func newRequest() *request {
-	return &request{
-		file: make(map[string]string),
-		body: make(map[string]interface{}),
-		head: url.Values{"User-Agent": []string{userAgent}},
+	req.format = p.Format
+	for k, v := range p.Headers {
+		req.head.Set(k, v)
 	}
+	return req
 }
 
 func (req *request) url() (u string) {
 	u = fmt.Sprintf("%s://%s/%s", req.scheme, req.host, strings.Join(req.path, "/"))
+	q := make(url.Values, len(req.query))
+	for key, vals := range req.query {
+		q[key] = vals
+	}
 	if req.method == "GET" && len(req.body) != 0 {
-		q := make(url.Values)
 		for key, value := range req.body {
 			q.Set(key, fmt.Sprintf("%v", value))
 		}
+	}
+	if len(q) != 0 {
 		u = fmt.Sprintf("%s?%s", u, q.Encode())
 	}
 	return
@@ -201,9 +109,17 @@ func (req *request) build() (r *http.Request, err error) {
 }
 
 func (req *request) reader() (_ io.Reader, err error) {
+	if len(req.file) == 1 && req.client.uploadResume {
+		for _, fname := range req.file {
+			return req.resumableUpload(fname)
+		}
+	}
 	if len(req.file) != 0 {
 		return req.mimeReader()
 	}
+	if req.rawBody != "" {
+		return strings.NewReader(req.rawBody), nil
+	}
 	if len(req.body) == 0 || req.method == "GET" {
 		return
 	}
@@ -228,40 +144,50 @@ func (req *request) formReader() (_ io.Reader, err error) {
 	return strings.NewReader(data.Encode()), nil
 }
 
-func (req *request) mimeReader() (_ io.Reader, err error) {
-	body := new(bytes.Buffer)
-	writer := multipart.NewWriter(body)
+// mimeReader streams a multipart body through an io.Pipe instead of
+// buffering it in memory, so uploading a large file doesn't require
+// holding the whole thing (and its multipart framing) in RAM at once.
+// Each file part is wrapped in a progress reader when stderr is a TTY.
+func (req *request) mimeReader() (io.Reader, error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	req.head.Set("Content-Type", writer.FormDataContentType())
+
+	go func() {
+		err := req.writeMimeParts(writer)
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+func (req *request) writeMimeParts(writer *multipart.Writer) error {
 	for key, fname := range req.file {
-		var part io.Writer
-		part, err = writer.CreateFormFile(key, filepath.Base(fname))
+		part, err := writer.CreateFormFile(key, filepath.Base(fname))
 		if err != nil {
-			return
+			return err
 		}
 
-		var file *os.File
-		file, err = os.Open(fname)
+		file, err := os.Open(fname)
 		if err != nil {
-			return
+			return err
 		}
-		defer file.Close()
 
-		_, err = io.Copy(part, file)
+		var src io.Reader = file
+		if info, err := file.Stat(); err == nil {
+			src = newProgressReader(file, fname, info.Size())
+		}
+		_, err = io.Copy(part, src)
+		file.Close()
 		if err != nil {
-			return
+			return err
 		}
 	}
 	for key, value := range req.body {
-		err = writer.WriteField(key, fmt.Sprintf("%v", value))
-		if err != nil {
-			return
+		if err := writer.WriteField(key, fmt.Sprintf("%v", value)); err != nil {
+			return err
 		}
 	}
-	err = writer.Close()
-	if err != nil {
-		return
-	}
-	req.head.Set("Content-Type", "multipart/form-data")
-	return body, nil
+	return writer.Close()
 }
 
 func (req *request) addHeader(h string) (err error) {
@@ -278,7 +204,7 @@ func splitKV(kv, del string) (key, value string, ok bool) {
 	if s == -1 {
 		return
 	}
-	return kv[:s], kv[s+1:], true
+	return kv[:s], kv[s+len(del):], true
 }
 
 func wrapString(s string) string {
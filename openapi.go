@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// openAPISpec wraps a loaded OpenAPI 3 document. It's kept as a raw node
+// tree rather than a fully typed document so loadSpec doesn't choke on the
+// many sibling keys (summary, parameters, servers, ...) an operation's
+// parent path item can carry.
+type openAPISpec struct {
+	raw map[string]interface{}
+}
+
+func loadSpec(path string) (*openAPISpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &openAPISpec{raw: raw}, nil
+}
+
+type oaSchema struct {
+	Type       string              `yaml:"type"`
+	Required   []string            `yaml:"required"`
+	Properties map[string]oaSchema `yaml:"properties"`
+}
+
+type oaMediaType struct {
+	Schema oaSchema `yaml:"schema"`
+}
+
+type oaParameter struct {
+	Name     string   `yaml:"name"`
+	In       string   `yaml:"in"`
+	Required bool     `yaml:"required"`
+	Schema   oaSchema `yaml:"schema"`
+}
+
+type oaOperation struct {
+	OperationID string        `yaml:"operationId"`
+	Parameters  []oaParameter `yaml:"parameters"`
+	RequestBody struct {
+		Required bool                   `yaml:"required"`
+		Content  map[string]oaMediaType `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]oaMediaType `yaml:"content"`
+	} `yaml:"responses"`
+}
+
+// findOperation matches method and pathSegments (the literal segments the
+// user passed on the command line) against the spec's path templates, e.g.
+// "/pet/{petId}" matches method=GET, pathSegments=["pet", "42"]. A path
+// param can also be left out of pathSegments entirely and auto-populated
+// from body instead (e.g. pathSegments=["pet"] with body["petId"]=42);
+// findOperation returns the fully resolved path segments for that case.
+func (s *openAPISpec) findOperation(method string, pathSegments []string, body map[string]interface{}) (*oaOperation, []string, error) {
+	paths, _ := s.raw["paths"].(map[string]interface{})
+	method = strings.ToLower(method)
+
+	for template, item := range paths {
+		templateSegments := splitPath(template)
+		resolved, ok := matchPathTemplate(templateSegments, pathSegments, body)
+		if !ok {
+			continue
+		}
+
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		node, ok := itemMap[method]
+		if !ok {
+			return nil, nil, fmt.Errorf("no %s operation defined for %s", strings.ToUpper(method), template)
+		}
+		var op oaOperation
+		if err := decodeNode(node, &op); err != nil {
+			return nil, nil, fmt.Errorf("decoding operation for %s %s: %w", strings.ToUpper(method), template, err)
+		}
+		return &op, resolved, nil
+	}
+	return nil, nil, fmt.Errorf("no operation matches %s /%s", strings.ToUpper(method), strings.Join(pathSegments, "/"))
+}
+
+// matchPathTemplate aligns templateSegments against pathSegments, resolving
+// each "{name}" segment positionally (the old behavior) unless body already
+// holds a value under that name, in which case the positional segment is
+// left for later literal segments to consume instead.
+func matchPathTemplate(templateSegments, pathSegments []string, body map[string]interface{}) ([]string, bool) {
+	resolved := make([]string, len(templateSegments))
+	pi := 0
+	for i, seg := range templateSegments {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			name := strings.Trim(seg, "{}")
+			if v, ok := body[name]; ok {
+				resolved[i] = fmt.Sprintf("%v", v)
+				continue
+			}
+			if pi >= len(pathSegments) {
+				return nil, false
+			}
+			resolved[i] = pathSegments[pi]
+			pi++
+			continue
+		}
+		if pi >= len(pathSegments) || pathSegments[pi] != seg {
+			return nil, false
+		}
+		resolved[i] = seg
+		pi++
+	}
+	if pi != len(pathSegments) {
+		return nil, false
+	}
+	return resolved, true
+}
+
+// decodeNode re-marshals a generically-decoded YAML node and unmarshals it
+// into a typed value, avoiding the need to hand-write a decoder for the
+// subset of the spec we actually care about.
+func decodeNode(node interface{}, out interface{}) error {
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, out)
+}
+
+// validateBody checks body against op's JSON request schema: required
+// fields must be present, and declared integer/boolean fields are
+// coerced from the strings/floats req.body holds after arg parsing.
+func (op *oaOperation) validateBody(body map[string]interface{}) error {
+	mt, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		return nil
+	}
+	schema := mt.Schema
+	for _, name := range schema.Required {
+		if _, ok := body[name]; !ok {
+			return fmt.Errorf("missing required field %q", name)
+		}
+	}
+	for name, prop := range schema.Properties {
+		v, ok := body[name]
+		if !ok {
+			continue
+		}
+		coerced, err := coerceValue(v, prop.Type)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", name, err)
+		}
+		body[name] = coerced
+	}
+	return nil
+}
+
+func coerceValue(v interface{}, schemaType string) (interface{}, error) {
+	switch schemaType {
+	case "integer":
+		switch t := v.(type) {
+		case float64:
+			return int64(t), nil
+		case string:
+			n, err := strconv.ParseInt(t, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("not an integer: %q", t)
+			}
+			return n, nil
+		}
+	case "boolean":
+		switch t := v.(type) {
+		case bool:
+			return t, nil
+		case string:
+			b, err := strconv.ParseBool(t)
+			if err != nil {
+				return nil, fmt.Errorf("not a boolean: %q", t)
+			}
+			return b, nil
+		}
+	}
+	return v, nil
+}
+
+// validateResponse checks body against op's declared response schema for
+// status and returns a warning string (not an error: a mismatch here
+// shouldn't stop req from printing the response).
+func (op *oaOperation) validateResponse(status int, body map[string]interface{}) string {
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		resp, ok = op.Responses["default"]
+		if !ok {
+			return ""
+		}
+	}
+	mt, ok := resp.Content["application/json"]
+	if !ok {
+		return ""
+	}
+	var missing []string
+	for _, name := range mt.Schema.Required {
+		if _, ok := body[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Sprintf("response missing required field(s): %s", strings.Join(missing, ", "))
+	}
+	return ""
+}
+
+// operationSummaries lists "METHOD /path operationId" for every operation
+// in the spec, one per line; used by `req complete` to feed shell
+// completion scripts.
+func (s *openAPISpec) operationSummaries() []string {
+	paths, _ := s.raw["paths"].(map[string]interface{})
+	var out []string
+	for template, item := range paths {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, method := range httpMethods {
+			node, ok := itemMap[method]
+			if !ok {
+				continue
+			}
+			var op oaOperation
+			if err := decodeNode(node, &op); err != nil {
+				continue
+			}
+			out = append(out, fmt.Sprintf("%s %s %s", strings.ToUpper(method), template, op.OperationID))
+		}
+	}
+	return out
+}
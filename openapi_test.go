@@ -0,0 +1,160 @@
+package main
+
+import "testing"
+
+func TestCoerceValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		v          interface{}
+		schemaType string
+		want       interface{}
+		wantErr    bool
+	}{
+		{"float to integer", float64(42), "integer", int64(42), false},
+		{"string to integer", "42", "integer", int64(42), false},
+		{"invalid integer string", "nope", "integer", nil, true},
+		{"bool passthrough", true, "boolean", true, false},
+		{"string to boolean", "true", "boolean", true, false},
+		{"invalid boolean string", "nope", "boolean", nil, true},
+		{"unknown type passthrough", "hi", "string", "hi", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := coerceValue(tt.v, tt.schemaType)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("coerceValue(%v, %q) expected an error, got none", tt.v, tt.schemaType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("coerceValue(%v, %q) error: %v", tt.v, tt.schemaType, err)
+			}
+			if got != tt.want {
+				t.Fatalf("coerceValue(%v, %q) = %#v, want %#v", tt.v, tt.schemaType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPathTemplate(t *testing.T) {
+	tests := []struct {
+		name         string
+		template     []string
+		path         []string
+		body         map[string]interface{}
+		wantResolved []string
+		wantOK       bool
+	}{
+		{
+			name:         "positional param",
+			template:     []string{"pet", "{petId}"},
+			path:         []string{"pet", "42"},
+			body:         map[string]interface{}{},
+			wantResolved: []string{"pet", "42"},
+			wantOK:       true,
+		},
+		{
+			name:         "param auto-populated from body",
+			template:     []string{"pet", "{petId}"},
+			path:         []string{"pet"},
+			body:         map[string]interface{}{"petId": 42},
+			wantResolved: []string{"pet", "42"},
+			wantOK:       true,
+		},
+		{
+			name:     "literal mismatch",
+			template: []string{"pet", "{petId}"},
+			path:     []string{"toy", "42"},
+			body:     map[string]interface{}{},
+			wantOK:   false,
+		},
+		{
+			name:     "missing param and no body value",
+			template: []string{"pet", "{petId}"},
+			path:     []string{"pet"},
+			body:     map[string]interface{}{},
+			wantOK:   false,
+		},
+		{
+			name:     "extra path segment",
+			template: []string{"pet", "{petId}"},
+			path:     []string{"pet", "42", "extra"},
+			body:     map[string]interface{}{},
+			wantOK:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := matchPathTemplate(tt.template, tt.path, tt.body)
+			if ok != tt.wantOK {
+				t.Fatalf("matchPathTemplate() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if len(got) != len(tt.wantResolved) {
+				t.Fatalf("matchPathTemplate() = %#v, want %#v", got, tt.wantResolved)
+			}
+			for i := range got {
+				if got[i] != tt.wantResolved[i] {
+					t.Fatalf("matchPathTemplate() = %#v, want %#v", got, tt.wantResolved)
+				}
+			}
+		})
+	}
+}
+
+func TestFindOperation(t *testing.T) {
+	spec := &openAPISpec{raw: map[string]interface{}{
+		"paths": map[string]interface{}{
+			"/pet/{petId}": map[string]interface{}{
+				"get": map[string]interface{}{
+					"operationId": "getPet",
+				},
+			},
+		},
+	}}
+
+	op, resolved, err := spec.findOperation("GET", []string{"pet", "42"}, map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.OperationID != "getPet" {
+		t.Fatalf("OperationID = %q, want getPet", op.OperationID)
+	}
+	if len(resolved) != 2 || resolved[0] != "pet" || resolved[1] != "42" {
+		t.Fatalf("resolved = %#v, want [pet 42]", resolved)
+	}
+
+	if _, _, err := spec.findOperation("GET", []string{"nope"}, map[string]interface{}{}); err == nil {
+		t.Fatal("expected an error for an unmatched path")
+	}
+}
+
+func TestValidateBody(t *testing.T) {
+	op := &oaOperation{}
+	op.RequestBody.Content = map[string]oaMediaType{
+		"application/json": {
+			Schema: oaSchema{
+				Required: []string{"name"},
+				Properties: map[string]oaSchema{
+					"name": {Type: "string"},
+					"age":  {Type: "integer"},
+				},
+			},
+		},
+	}
+
+	if err := op.validateBody(map[string]interface{}{"age": "30"}); err == nil {
+		t.Fatal("expected missing required field error")
+	}
+
+	body := map[string]interface{}{"name": "Ada", "age": "30"}
+	if err := op.validateBody(body); err != nil {
+		t.Fatalf("validateBody() error: %v", err)
+	}
+	if body["age"] != int64(30) {
+		t.Fatalf("age = %#v, want coerced int64(30)", body["age"])
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// progressReader wraps an io.Reader and renders bytes read / throughput /
+// ETA to stderr as it's consumed, when stderr is a TTY. total may be 0 if
+// the size isn't known in advance (e.g. a streamed request body), in
+// which case no percentage or ETA is shown.
+type progressReader struct {
+	r     io.Reader
+	label string
+	total int64
+	read  int64
+
+	start     time.Time
+	lastPrint time.Time
+	tty       bool
+}
+
+func newProgressReader(r io.Reader, label string, total int64) *progressReader {
+	return &progressReader{
+		r:     r,
+		label: label,
+		total: total,
+		start: time.Now(),
+		tty:   isTTY(os.Stderr),
+	}
+}
+
+// startAt seeds the counters for a resumed transfer so throughput/ETA are
+// computed over the remaining bytes, not the whole file.
+func (p *progressReader) startAt(n int64) *progressReader {
+	p.read = n
+	return p
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.read += int64(n)
+	if p.tty {
+		p.print(err)
+	}
+	return n, err
+}
+
+func (p *progressReader) print(err error) {
+	now := time.Now()
+	done := err != nil
+	if !done && now.Sub(p.lastPrint) < 100*time.Millisecond {
+		return
+	}
+	p.lastPrint = now
+
+	elapsed := now.Sub(p.start).Seconds()
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(p.read) / elapsed
+	}
+
+	if p.total > 0 {
+		pct := float64(p.read) / float64(p.total) * 100
+		eta := "?"
+		if rate > 0 && p.read < p.total {
+			eta = time.Duration(float64(p.total-p.read) / rate * float64(time.Second)).Round(time.Second).String()
+		}
+		fmt.Fprintf(os.Stderr, "\r%s %5.1f%% %s/%s %s/s eta %s   ", p.label, pct, humanBytes(p.read), humanBytes(p.total), humanBytes(int64(rate)), eta)
+	} else {
+		fmt.Fprintf(os.Stderr, "\r%s %s %s/s   ", p.label, humanBytes(p.read), humanBytes(int64(rate)))
+	}
+	if done {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
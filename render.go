@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// renderResponse writes resp's body to stdout, pretty-printing it when the
+// Content-Type is recognized and applying req.selectExpr when set.
+func renderResponse(req *request, resp *http.Response) error {
+	ct := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(ct)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case strings.Contains(mediaType, "json"):
+		return renderJSON(req, body)
+	case mediaType == "application/x-www-form-urlencoded":
+		return renderForm(body)
+	case strings.Contains(mediaType, "xml"):
+		return renderXML(body)
+	default:
+		_, err = os.Stdout.Write(body)
+		return err
+	}
+}
+
+func renderJSON(req *request, body []byte) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		// not actually valid JSON despite the content type, dump raw
+		_, err = os.Stdout.Write(body)
+		return err
+	}
+
+	if req.selectExpr != "" {
+		selected, err := selectPath(v, req.selectExpr)
+		if err != nil {
+			return fmt.Errorf("--select: %w", err)
+		}
+		v = selected
+	}
+
+	if items, ok := v.([]interface{}); ok && req.selectExpr != "" {
+		enc := json.NewEncoder(os.Stdout)
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if isTTY(os.Stdout) {
+		out = colorizeJSON(out)
+	}
+	os.Stdout.Write(out)
+	fmt.Fprintln(os.Stdout)
+	return nil
+}
+
+func renderForm(body []byte) error {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return err
+	}
+	for key, vals := range values {
+		for _, v := range vals {
+			fmt.Fprintf(os.Stdout, "%s=%s\n", key, v)
+		}
+	}
+	return nil
+}
+
+func renderXML(body []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	out := new(bytes.Buffer)
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			os.Stdout.Write(body)
+			return nil
+		}
+		if err := encoder.EncodeToken(tok); err != nil {
+			return err
+		}
+	}
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+	os.Stdout.Write(out.Bytes())
+	fmt.Fprintln(os.Stdout)
+	return nil
+}
+
+func isTTY(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+const (
+	colorKey    = "\x1b[34m"
+	colorString = "\x1b[32m"
+	colorNum    = "\x1b[33m"
+	colorLit    = "\x1b[35m"
+	colorReset  = "\x1b[0m"
+)
+
+// colorizeJSON adds ANSI color to the output of json.MarshalIndent. It is a
+// cheap token-based pass, not a full parser, but MarshalIndent's output is
+// regular enough that this is safe.
+func colorizeJSON(in []byte) []byte {
+	out := new(bytes.Buffer)
+	inString := false
+	isKey := false
+	for i := 0; i < len(in); i++ {
+		c := in[i]
+		switch {
+		case c == '"' && (i == 0 || in[i-1] != '\\'):
+			if !inString {
+				inString = true
+				isKey = isKeyPosition(in, i)
+				if isKey {
+					out.WriteString(colorKey)
+				} else {
+					out.WriteString(colorString)
+				}
+				out.WriteByte(c)
+			} else {
+				inString = false
+				out.WriteByte(c)
+				out.WriteString(colorReset)
+			}
+		case inString:
+			out.WriteByte(c)
+		case c == 't' && bytes.HasPrefix(in[i:], []byte("true")):
+			out.WriteString(colorLit + "true" + colorReset)
+			i += 3
+		case c == 'f' && bytes.HasPrefix(in[i:], []byte("false")):
+			out.WriteString(colorLit + "false" + colorReset)
+			i += 4
+		case c == 'n' && bytes.HasPrefix(in[i:], []byte("null")):
+			out.WriteString(colorLit + "null" + colorReset)
+			i += 3
+		case (c >= '0' && c <= '9') || c == '-':
+			start := i
+			for i < len(in) && (in[i] == '.' || in[i] == '-' || in[i] == '+' || in[i] == 'e' || in[i] == 'E' || (in[i] >= '0' && in[i] <= '9')) {
+				i++
+			}
+			out.WriteString(colorNum)
+			out.Write(in[start:i])
+			out.WriteString(colorReset)
+			i--
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.Bytes()
+}
+
+// isKeyPosition reports whether the `"` at offset i opens an object key
+// rather than a string value, based on the preceding non-space byte.
+func isKeyPosition(in []byte, i int) bool {
+	for j := i - 1; j >= 0; j-- {
+		switch in[j] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '{', ',':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
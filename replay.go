@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// replayRecord is a minimal, self-contained capture of an HTTP request:
+// enough to resend it verbatim without going through the request builder.
+type replayRecord struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+func newReplayCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "replay <file>",
+		Short: "Resend a request captured to a JSON file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rec, err := loadReplayRecord(args[0])
+			if err != nil {
+				return err
+			}
+			return replay(rec)
+		},
+	}
+}
+
+// recordRequest captures a built *http.Request to path as a replayRecord,
+// the counterpart `req replay` reads back. It drains and restores r.Body
+// so the caller can still send the request afterwards.
+func recordRequest(path string, r *http.Request) error {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	rec := replayRecord{
+		Method: r.Method,
+		URL:    r.URL.String(),
+		Header: r.Header,
+		Body:   string(body),
+	}
+	raw, err := json.MarshalIndent(&rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+func loadReplayRecord(path string) (*replayRecord, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec replayRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func replay(rec *replayRecord) error {
+	r, err := http.NewRequest(rec.Method, rec.URL, strings.NewReader(rec.Body))
+	if err != nil {
+		return err
+	}
+	for key, values := range rec.Header {
+		r.Header[key] = values
+	}
+
+	req := newRequest(nil)
+	cfg := newClientConfig()
+	httpClient, err := cfg.client()
+	if err != nil {
+		return err
+	}
+
+	resp, err := do(httpClient, r, cfg)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if flagDebug || flagVerbose {
+		return resp.Write(os.Stdout)
+	}
+	return renderResponse(req, resp)
+}
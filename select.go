@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// selectPath evaluates a small JMESPath-like expression against a decoded
+// JSON value. It supports dotted field access, `[index]`, `[*]`, and a
+// single equality filter per step: `[?field=="value"]`.
+//
+// It is intentionally not a full JMESPath implementation: just enough to
+// cover `items[*].email`-style pipelines out of API responses.
+func selectPath(v interface{}, expr string) (interface{}, error) {
+	steps, err := tokenizeSelect(expr)
+	if err != nil {
+		return nil, err
+	}
+	cur := v
+	for _, step := range steps {
+		cur, err = applySelectStep(cur, step)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cur, nil
+}
+
+type selectStep struct {
+	field     string // "" for a bare index/wildcard/filter step
+	index     int
+	wildcard  bool
+	hasIndex  bool
+	filterKey string
+	filterVal string
+	hasFilter bool
+}
+
+// tokenizeSelect splits "items[*].email" / "users[?id==\"1\"].name" into
+// steps, splitting on `.` outside of brackets.
+func tokenizeSelect(expr string) ([]selectStep, error) {
+	var steps []selectStep
+	for _, part := range splitPathExpr(expr) {
+		if part == "" {
+			continue
+		}
+		field := part
+		var bracket string
+		if idx := strings.Index(part, "["); idx != -1 {
+			if !strings.HasSuffix(part, "]") {
+				return nil, fmt.Errorf("unbalanced brackets in %q", part)
+			}
+			field = part[:idx]
+			bracket = part[idx+1 : len(part)-1]
+		}
+		step := selectStep{field: field}
+		if bracket != "" {
+			switch {
+			case bracket == "*":
+				step.wildcard = true
+			case strings.HasPrefix(bracket, "?"):
+				key, val, ok := splitKV(strings.TrimPrefix(bracket, "?"), "==")
+				if !ok {
+					return nil, fmt.Errorf("invalid filter %q", bracket)
+				}
+				step.hasFilter = true
+				step.filterKey = strings.TrimSpace(key)
+				step.filterVal = strings.Trim(strings.TrimSpace(val), `"`)
+			default:
+				n, err := strconv.Atoi(bracket)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q", bracket)
+				}
+				step.hasIndex = true
+				step.index = n
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// splitPathExpr splits on '.' but keeps "field[...]" together.
+func splitPathExpr(expr string) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	for i, r := range expr {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				parts = append(parts, expr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, expr[start:])
+	return parts
+}
+
+func applySelectStep(v interface{}, step selectStep) (interface{}, error) {
+	if step.field != "" {
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			var ok2 bool
+			v, ok2 = vv[step.field]
+			if !ok2 {
+				return nil, nil
+			}
+		case []interface{}:
+			// A prior [*]/[?...] step left us with a list; map the field
+			// access over each element instead of indexing the list itself.
+			mapped := make([]interface{}, len(vv))
+			for i, item := range vv {
+				m, ok := item.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("cannot select field %q from %T", step.field, item)
+				}
+				mapped[i] = m[step.field]
+			}
+			v = mapped
+		default:
+			return nil, fmt.Errorf("cannot select field %q from %T", step.field, v)
+		}
+	}
+
+	switch {
+	case step.wildcard:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot apply [*] to %T", v)
+		}
+		return arr, nil
+	case step.hasFilter:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot apply filter to %T", v)
+		}
+		var out []interface{}
+		for _, item := range arr {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if fmt.Sprintf("%v", m[step.filterKey]) == step.filterVal {
+				out = append(out, item)
+			}
+		}
+		return out, nil
+	case step.hasIndex:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot index into %T", v)
+		}
+		if step.index < 0 || step.index >= len(arr) {
+			return nil, fmt.Errorf("index %d out of range", step.index)
+		}
+		return arr[step.index], nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"name": "Ada",
+		"items": []interface{}{
+			map[string]interface{}{"email": "a@x.com", "active": true},
+			map[string]interface{}{"email": "b@x.com", "active": false},
+		},
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want interface{}
+	}{
+		{"dotted field", "name", "Ada"},
+		{"index into array", "items[0].email", "a@x.com"},
+		{"wildcard", "items[*].email", []interface{}{"a@x.com", "b@x.com"}},
+		{"filter", `items[?active=="true"].email`, []interface{}{"a@x.com"}},
+		{"missing field", "nope", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectPath(doc, tt.expr)
+			if err != nil {
+				t.Fatalf("selectPath(%q) error: %v", tt.expr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("selectPath(%q) = %#v, want %#v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectPathErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		expr string
+	}{
+		{"field on array of non-objects", []interface{}{"a", "b"}, "items[*].email"},
+		{"index out of range", map[string]interface{}{"items": []interface{}{"a"}}, "items[5]"},
+		{"unbalanced brackets", nil, "items[*"},
+		{"invalid index", nil, "items[x]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := tt.v
+			if v == nil {
+				v = map[string]interface{}{}
+			}
+			if _, err := selectPath(v, tt.expr); err == nil {
+				t.Fatalf("selectPath(%q) expected an error, got none", tt.expr)
+			}
+		})
+	}
+}
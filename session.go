@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// sessionData is the on-disk snapshot written by `req session save` and
+// updated automatically by `--session` invocations: the request defaults
+// plus whatever cookies and OAuth2 tokens the session has accumulated.
+type sessionData struct {
+	Scheme  string            `json:"scheme"`
+	Host    string            `json:"host"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Format  string            `json:"format"`
+	Cookies []*http.Cookie    `json:"cookies,omitempty"`
+	OAuth2  *oauth2Token      `json:"oauth2,omitempty"`
+}
+
+func sessionDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "req", "sessions"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "req", "sessions"), nil
+}
+
+func sessionPath(name string) (string, error) {
+	dir, err := sessionDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// loadSession reads the named session, returning an empty, non-nil
+// sessionData (not an error) if none has been saved yet.
+func loadSession(name string) (*sessionData, error) {
+	path, err := sessionPath(name)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &sessionData{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var data sessionData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func saveSessionData(name string, data *sessionData) error {
+	path, err := sessionPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0o600)
+}
+
+// saveSession snapshots req's host/headers/format into the named session,
+// preserving any cookies or OAuth2 tokens it has already accumulated.
+func saveSession(name string, req *request) error {
+	data, err := loadSession(name)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]string, len(req.head))
+	for k, v := range req.head {
+		if len(v) > 0 {
+			headers[k] = v[0]
+		}
+	}
+
+	data.Scheme = req.scheme
+	data.Host = req.host
+	data.Path = strings.Join(req.path, "/")
+	data.Headers = headers
+	data.Format = req.format
+	return saveSessionData(name, data)
+}
+
+// applySession loads the named session into req: its headers and cookie
+// jar (scoped to req's host) become the client's defaults, to be
+// overridden by whatever explicit flags already ran.
+func applySession(req *request, name string) (*sessionData, error) {
+	data, err := loadSession(name)
+	if err != nil {
+		return nil, err
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Cookies) > 0 && req.host != "" {
+		jar.SetCookies(&url.URL{Scheme: req.scheme, Host: req.host}, data.Cookies)
+	}
+	req.client.jar = jar
+
+	for k, v := range data.Headers {
+		if req.head.Get(k) == "" {
+			req.head.Set(k, v)
+		}
+	}
+	return data, nil
+}
+
+// persistSessionCookies writes back whatever cookies the jar picked up
+// from Set-Cookie headers on the response to reqURL.
+func persistSessionCookies(name string, jar http.CookieJar, reqURL *url.URL) error {
+	data, err := loadSession(name)
+	if err != nil {
+		return err
+	}
+	data.Cookies = jar.Cookies(reqURL)
+	return saveSessionData(name, data)
+}
+
+func newSessionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "session",
+		Short: "Save and load named request sessions",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "save <name>",
+		Short: "Save the current flags (host, headers, auth, format) as a named session",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req, err := newRequestFromFlags()
+			if err != nil {
+				return err
+			}
+			return saveSession(args[0], req)
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "load <name>",
+		Short: "Print a saved session as JSON",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := loadSession(args[0])
+			if err != nil {
+				return err
+			}
+			out, err := json.MarshalIndent(data, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		},
+	})
+	return cmd
+}
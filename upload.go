@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// resumableUpload streams the single file in req.file directly as the
+// request body (no multipart envelope) for servers that support
+// resuming: it HEAD-probes the target for how much of the upload it
+// already has (a tus.io "Upload-Offset" header, or the upper bound of a
+// "Content-Range"), seeks past those bytes, and sends only the rest.
+func (req *request) resumableUpload(fname string) (io.Reader, error) {
+	file, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	size := info.Size()
+
+	offset, err := req.probeUploadOffset()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if offset > 0 {
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	req.head.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, size-1, size))
+	req.head.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+
+	return newProgressReader(file, fname, size).startAt(offset), nil
+}
+
+// probeUploadOffset HEADs the request's target URL and reports how much
+// of the upload the server claims to already have, or 0 if it doesn't
+// understand the probe.
+func (req *request) probeUploadOffset() (int64, error) {
+	client, err := req.client.client()
+	if err != nil {
+		return 0, err
+	}
+	r, err := http.NewRequest(http.MethodHead, req.url(), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		return 0, nil
+	}
+	defer resp.Body.Close()
+
+	if v := resp.Header.Get("Upload-Offset"); v != "" {
+		return strconv.ParseInt(v, 10, 64)
+	}
+	if v := resp.Header.Get("Content-Range"); v != "" {
+		if n, ok := parseContentRangeEnd(v); ok {
+			return n, nil
+		}
+	}
+	return 0, nil
+}
+
+// parseContentRangeEnd extracts the byte count already stored from a
+// "bytes start-end/total" Content-Range value.
+func parseContentRangeEnd(v string) (int64, bool) {
+	v = strings.TrimPrefix(v, "bytes ")
+	parts := strings.SplitN(v, "/", 2)
+	if len(parts) != 2 {
+		return 0, false
+	}
+	rng := strings.SplitN(parts[0], "-", 2)
+	if len(rng) != 2 {
+		return 0, false
+	}
+	end, err := strconv.ParseInt(rng[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return end + 1, true
+}
@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestParseContentRangeEnd(t *testing.T) {
+	tests := []struct {
+		name   string
+		v      string
+		want   int64
+		wantOK bool
+	}{
+		{"well-formed", "bytes 0-499/1234", 500, true},
+		{"no total", "bytes 0-499", 0, false},
+		{"no range", "bytes /1234", 0, false},
+		{"non-numeric end", "bytes 0-abc/1234", 0, false},
+		{"empty", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseContentRangeEnd(tt.v)
+			if ok != tt.wantOK {
+				t.Fatalf("parseContentRangeEnd(%q) ok = %v, want %v", tt.v, ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Fatalf("parseContentRangeEnd(%q) = %d, want %d", tt.v, got, tt.want)
+			}
+		})
+	}
+}